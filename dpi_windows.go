@@ -0,0 +1,100 @@
+package win
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/gonutz/w32"
+)
+
+// baseDPI is the DPI at 100% scaling, used as the reference point for
+// converting between logical pixels and physical pixels.
+const baseDPI = 96
+
+// declareDPIAware tells Windows that this process handles scaling itself,
+// at the finest granularity the running Windows version supports. Newer
+// systems get per-monitor-v2 awareness; on systems that do not support it,
+// it falls back to per-monitor awareness and finally to the older
+// system-DPI-only awareness.
+func declareDPIAware() {
+	if w32.SetProcessDpiAwarenessContext(w32.DPI_AWARENESS_CONTEXT_PER_MONITOR_AWARE_V2) {
+		return
+	}
+	if w32.SetProcessDpiAwareness(w32.PROCESS_PER_MONITOR_DPI_AWARE) == w32.S_OK {
+		return
+	}
+	w32.SetProcessDPIAware()
+}
+
+// NewWindowDPI creates a window like NewWindow does, but first declares the
+// process as per-monitor DPI aware and interprets x, y, width and height as
+// logical pixels at 96 DPI (the Windows default). They are scaled by the DPI
+// of the monitor the window is initially created on before being passed to
+// CreateWindowEx, so the window has the same physical size on every monitor
+// regardless of its scaling setting.
+func NewWindowDPI(x, y, width, height int, className string, f MessageCallback) (w32.HWND, error) {
+	declareDPIAware()
+
+	dpi := dpiForPoint(x, y)
+	scale := float64(dpi) / baseDPI
+	scaledX := int(float64(x) * scale)
+	scaledY := int(float64(y) * scale)
+	scaledWidth := int(float64(width) * scale)
+	scaledHeight := int(float64(height) * scale)
+
+	return NewWindow(scaledX, scaledY, scaledWidth, scaledHeight, className, f)
+}
+
+// dpiForPoint returns the DPI of the monitor containing the given point, in
+// virtual screen coordinates, defaulting to baseDPI if it cannot be
+// determined.
+func dpiForPoint(x, y int) int {
+	monitor := w32.MonitorFromPoint(w32.POINT{X: int32(x), Y: int32(y)}, w32.MONITOR_DEFAULTTOPRIMARY)
+	return dpiForMonitor(monitor)
+}
+
+// dpiForMonitor returns the DPI of the given monitor, defaulting to baseDPI
+// if it cannot be determined.
+func dpiForMonitor(monitor w32.HMONITOR) int {
+	dpiX, _, err := w32.GetDpiForMonitor(monitor, w32.MDT_EFFECTIVE_DPI)
+	if err != nil || dpiX == 0 {
+		return baseDPI
+	}
+	return int(dpiX)
+}
+
+// GetWindowDPI returns the DPI that Windows currently associates with
+// window's monitor.
+func GetWindowDPI(window w32.HWND) int {
+	monitor := w32.MonitorFromWindow(window, w32.MONITOR_DEFAULTTOPRIMARY)
+	return dpiForMonitor(monitor)
+}
+
+// ScaleForWindow returns the scaling factor (1.0 at 96 DPI, 1.5 at 144 DPI,
+// and so on) that applies to window's monitor right now.
+func ScaleForWindow(window w32.HWND) float64 {
+	return float64(GetWindowDPI(window)) / baseDPI
+}
+
+// AdjustOnDPIChange resizes and repositions window in response to a
+// WM_DPICHANGED message. Call it with the message's lParam, which points to
+// a RECT with the suggested new window position and size for the new DPI.
+func AdjustOnDPIChange(window w32.HWND, lParam uintptr) error {
+	if lParam == 0 {
+		return errors.New("win.AdjustOnDPIChange: lParam is nil")
+	}
+	rect := (*w32.RECT)(unsafe.Pointer(lParam))
+	ok := w32.SetWindowPos(
+		window,
+		0,
+		int(rect.Left),
+		int(rect.Top),
+		int(rect.Right-rect.Left),
+		int(rect.Bottom-rect.Top),
+		w32.SWP_NOZORDER|w32.SWP_NOACTIVATE,
+	)
+	if !ok {
+		return errors.New("win.AdjustOnDPIChange: SetWindowPos failed")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package win
+
+import "github.com/gonutz/w32"
+
+// VirtualKey is a Windows virtual-key code, as sent in WM_KEYDOWN/WM_KEYUP
+// and WM_SYSKEYDOWN/WM_SYSKEYUP messages.
+type VirtualKey uintptr
+
+// MouseButtons is a bit mask of mouse buttons, as reported by OnMouseMove.
+type MouseButtons uint32
+
+const (
+	MouseButtonLeft MouseButtons = 1 << iota
+	MouseButtonRight
+	MouseButtonMiddle
+)
+
+// Handler is a set of typed callbacks for the messages a window normally has
+// to decode wParam/lParam for by hand. Every field is optional; nil callbacks
+// are simply not invoked. Use NewWindowWithHandler to create a window whose
+// MessageCallback dispatches to a Handler.
+type Handler struct {
+	OnKeyDown    func(key VirtualKey, repeat bool)
+	OnKeyUp      func(key VirtualKey)
+	OnChar       func(r rune)
+	OnMouseMove  func(x, y int, buttons MouseButtons)
+	OnMouseWheel func(delta int)
+	OnSize       func(width, height int)
+	OnPaint      func()
+	OnClose      func() bool
+	OnDPIChanged func(dpi int)
+}
+
+// NewWindowWithHandler creates a window like NewWindow does, but instead of a
+// raw MessageCallback it takes a Handler whose typed callbacks are invoked
+// for the corresponding window messages. Messages that are not covered by h
+// fall through to DefWindowProc.
+func NewWindowWithHandler(x, y, width, height int, className string, h *Handler) (w32.HWND, error) {
+	return NewWindow(x, y, width, height, className, func(window w32.HWND, msg uint32, w, l uintptr) uintptr {
+		return dispatchToHandler(h, window, msg, w, l)
+	})
+}
+
+// dispatchToHandler decodes msg/w/l and calls the matching callback on h, if
+// set, falling back to DefWindowProc for anything it does not handle or that
+// has no callback.
+func dispatchToHandler(h *Handler, window w32.HWND, msg uint32, w, l uintptr) uintptr {
+	switch msg {
+	case w32.WM_KEYDOWN, w32.WM_SYSKEYDOWN:
+		if h.OnKeyDown != nil {
+			repeat := l&(1<<30) != 0
+			h.OnKeyDown(VirtualKey(w), repeat)
+			return 0
+		}
+	case w32.WM_KEYUP, w32.WM_SYSKEYUP:
+		if h.OnKeyUp != nil {
+			h.OnKeyUp(VirtualKey(w))
+			return 0
+		}
+	case w32.WM_CHAR:
+		if h.OnChar != nil {
+			h.OnChar(rune(w))
+			return 0
+		}
+	case w32.WM_MOUSEMOVE:
+		if h.OnMouseMove != nil {
+			x, y := getXLParam(l), getYLParam(l)
+			h.OnMouseMove(x, y, mouseButtonsFromWParam(w))
+			return 0
+		}
+	case w32.WM_MOUSEWHEEL:
+		if h.OnMouseWheel != nil {
+			h.OnMouseWheel(int(int16(w >> 16)))
+			return 0
+		}
+	case w32.WM_SIZE:
+		if h.OnSize != nil {
+			h.OnSize(getXLParam(l), getYLParam(l))
+			return 0
+		}
+	case w32.WM_PAINT:
+		if h.OnPaint != nil {
+			var ps w32.PAINTSTRUCT
+			w32.BeginPaint(window, &ps)
+			h.OnPaint()
+			w32.EndPaint(window, &ps)
+			return 0
+		}
+	case w32.WM_CLOSE:
+		if h.OnClose != nil {
+			if h.OnClose() {
+				w32.DestroyWindow(window)
+			}
+			return 0
+		}
+	case w32.WM_DPICHANGED:
+		// Apply the suggested window rect so the window actually
+		// resizes/repositions for the new DPI; without this, callers using
+		// the high-level Handler API would see a DPI change notification but
+		// nothing would happen on screen.
+		AdjustOnDPIChange(window, l)
+		if h.OnDPIChanged != nil {
+			h.OnDPIChanged(int(w >> 16))
+		}
+		return 0
+	case w32.WM_DESTROY:
+		// Release any icon set via SetIconFromImage/SetIconsFromImages so
+		// Handler users get that cleanup for free. Posting WM_QUIT is the
+		// usual WM_DESTROY boilerplate; it is what RunMainLoop and
+		// RunMainGameLoop wait for.
+		ReleaseWindowIcon(window)
+		w32.PostQuitMessage(0)
+		return 0
+	}
+	return w32.DefWindowProc(window, msg, w, l)
+}
+
+// getXLParam and getYLParam extract the packed x and y coordinates from an
+// lParam, as used by WM_MOUSEMOVE, WM_SIZE and similar messages (the
+// GET_X_LPARAM/GET_Y_LPARAM macros).
+func getXLParam(l uintptr) int {
+	return int(int16(l & 0xFFFF))
+}
+
+func getYLParam(l uintptr) int {
+	return int(int16(l >> 16 & 0xFFFF))
+}
+
+// mouseButtonsFromWParam decodes the MK_* button flags carried in the wParam
+// of WM_MOUSEMOVE into a MouseButtons bit mask.
+func mouseButtonsFromWParam(w uintptr) MouseButtons {
+	var buttons MouseButtons
+	if w&w32.MK_LBUTTON != 0 {
+		buttons |= MouseButtonLeft
+	}
+	if w&w32.MK_RBUTTON != 0 {
+		buttons |= MouseButtonRight
+	}
+	if w&w32.MK_MBUTTON != 0 {
+		buttons |= MouseButtonMiddle
+	}
+	return buttons
+}
@@ -0,0 +1,122 @@
+package win
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32"
+)
+
+// monitorDeviceName returns the device name of the given monitor (e.g.
+// `\\.\DISPLAY1`), as required by EnumDisplaySettings and
+// ChangeDisplaySettingsEx to target a specific display instead of the
+// primary one.
+func monitorDeviceName(monitor w32.HMONITOR) (string, error) {
+	var info w32.MONITORINFO
+	if !w32.GetMonitorInfo(monitor, &info) {
+		return "", errors.New("win.monitorDeviceName: GetMonitorInfo failed")
+	}
+	return syscall.UTF16ToString(info.SzDevice[:]), nil
+}
+
+// EnableBorderlessFullscreenOnMonitor makes the window a borderless window
+// that covers the full area of the given monitor, as returned for example by
+// w32.MonitorFromPoint or w32.EnumDisplayMonitors. Unlike EnableFullscreen,
+// which always uses the monitor the window currently happens to be on, this
+// lets the caller pick any monitor.
+// It returns the previous window placement. Store that value and use it with
+// DisableFullscreen to reset the window to what it was before.
+func EnableBorderlessFullscreenOnMonitor(window w32.HWND, monitor w32.HMONITOR) (windowed w32.WINDOWPLACEMENT, err error) {
+	style := w32.GetWindowLong(window, w32.GWL_STYLE)
+	var monitorInfo w32.MONITORINFO
+	if !w32.GetWindowPlacement(window, &windowed) {
+		return windowed, errors.New("win.EnableBorderlessFullscreenOnMonitor: GetWindowPlacement failed")
+	}
+	if !w32.GetMonitorInfo(monitor, &monitorInfo) {
+		return windowed, errors.New("win.EnableBorderlessFullscreenOnMonitor: GetMonitorInfo failed")
+	}
+
+	w32.SetWindowLong(window, w32.GWL_STYLE, uint32(style & ^w32.WS_OVERLAPPEDWINDOW))
+	w32.SetWindowPos(
+		window,
+		0,
+		int(monitorInfo.RcMonitor.Left),
+		int(monitorInfo.RcMonitor.Top),
+		int(monitorInfo.RcMonitor.Right-monitorInfo.RcMonitor.Left),
+		int(monitorInfo.RcMonitor.Bottom-monitorInfo.RcMonitor.Top),
+		w32.SWP_NOOWNERZORDER|w32.SWP_FRAMECHANGED,
+	)
+	w32.ShowCursor(false)
+	return windowed, nil
+}
+
+// DisplaySettings is the previous display mode as returned by
+// EnableExclusiveFullscreen, to be passed to DisableExclusiveFullscreen to
+// restore it.
+type DisplaySettings struct {
+	device  string
+	devMode w32.DEVMODE
+}
+
+// EnableExclusiveFullscreen switches the display that window is on to the
+// given resolution and refresh rate (refreshHz may be 0 to keep the current
+// refresh rate) using ChangeDisplaySettingsEx, and resizes window to cover
+// the whole screen. It returns the display settings that were active before
+// the change, to be restored with DisableExclusiveFullscreen.
+func EnableExclusiveFullscreen(window w32.HWND, width, height, refreshHz int) (DisplaySettings, error) {
+	monitor := w32.MonitorFromWindow(window, w32.MONITOR_DEFAULTTOPRIMARY)
+	device, err := monitorDeviceName(monitor)
+	if err != nil {
+		return DisplaySettings{}, err
+	}
+
+	var previous w32.DEVMODE
+	previous.Size = uint16(unsafe.Sizeof(previous))
+	if !w32.EnumDisplaySettings(device, w32.ENUM_CURRENT_SETTINGS, &previous) {
+		return DisplaySettings{}, errors.New("win.EnableExclusiveFullscreen: EnumDisplaySettings failed")
+	}
+
+	mode := previous
+	mode.PelsWidth = uint32(width)
+	mode.PelsHeight = uint32(height)
+	mode.Fields = w32.DM_PELSWIDTH | w32.DM_PELSHEIGHT
+	if refreshHz > 0 {
+		mode.DisplayFrequency = uint32(refreshHz)
+		mode.Fields |= w32.DM_DISPLAYFREQUENCY
+	}
+
+	result := w32.ChangeDisplaySettingsEx(device, &mode, 0, w32.CDS_FULLSCREEN, 0)
+	if result != w32.DISP_CHANGE_SUCCESSFUL {
+		return DisplaySettings{}, errors.New("win.EnableExclusiveFullscreen: ChangeDisplaySettingsEx failed")
+	}
+
+	x, y := 0, 0
+	var monitorInfo w32.MONITORINFO
+	if w32.GetMonitorInfo(monitor, &monitorInfo) {
+		x, y = int(monitorInfo.RcMonitor.Left), int(monitorInfo.RcMonitor.Top)
+	}
+
+	style := w32.GetWindowLong(window, w32.GWL_STYLE)
+	w32.SetWindowLong(window, w32.GWL_STYLE, uint32(style & ^w32.WS_OVERLAPPEDWINDOW))
+	w32.SetWindowPos(
+		window, 0,
+		x, y, width, height,
+		w32.SWP_NOOWNERZORDER|w32.SWP_FRAMECHANGED,
+	)
+
+	return DisplaySettings{device: device, devMode: previous}, nil
+}
+
+// DisableExclusiveFullscreen restores the display mode that was active
+// before EnableExclusiveFullscreen was called, and restores window's normal
+// border via placement (as returned by EnableFullscreen or
+// GetWindowPlacement before switching to exclusive fullscreen).
+func DisableExclusiveFullscreen(window w32.HWND, previous DisplaySettings, placement w32.WINDOWPLACEMENT) error {
+	result := w32.ChangeDisplaySettingsEx(previous.device, &previous.devMode, 0, 0, 0)
+	if result != w32.DISP_CHANGE_SUCCESSFUL {
+		return errors.New("win.DisableExclusiveFullscreen: ChangeDisplaySettingsEx failed")
+	}
+	DisableFullscreen(window, placement)
+	return nil
+}
@@ -0,0 +1,171 @@
+package win
+
+import (
+	"errors"
+	"image"
+	"sync"
+
+	"github.com/gonutz/w32"
+)
+
+// windowIconPair is the pair of icon handles currently owned by a window, as
+// set by SetIconFromImage or SetIconsFromImages. Small and Big are the same
+// handle when SetIconFromImage was used.
+type windowIconPair struct {
+	Small, Big w32.HICON
+}
+
+var (
+	windowIconsMu sync.Mutex
+	windowIcons   = make(map[w32.HWND]windowIconPair)
+)
+
+// SetIconFromImage sets the icon in the window title bar, in the taskbar and
+// when using Alt-Tab to switch between applications.
+// Unlike SetIconFromExe, the icon does not have to be embedded in the
+// executable at build time. Instead it is created at run time from the given
+// image.Image, converted to a 32-bit BGRA icon.
+// Any icon previously set on the window with SetIconFromImage or
+// SetIconsFromImages is destroyed and replaced.
+func SetIconFromImage(window w32.HWND, img image.Image) error {
+	icon, err := createIconFromImage(img)
+	if err != nil {
+		return err
+	}
+	setWindowIcon(window, icon, icon, icon)
+	return nil
+}
+
+// SetIconsFromImages sets the icon in the window title bar, in the taskbar
+// and when using Alt-Tab to switch between applications, like
+// SetIconFromImage does. In addition it picks the best matching image for
+// the small icon (title bar, Alt-Tab list) and the big icon (taskbar,
+// Alt-Tab preview) from the given images, comparing their size to
+// GetSystemMetrics(SM_CXSMICON) and GetSystemMetrics(SM_CXICON)
+// respectively. If images is empty, an error is returned.
+func SetIconsFromImages(window w32.HWND, images []image.Image) error {
+	if len(images) == 0 {
+		return errors.New("win.SetIconsFromImages: no images given")
+	}
+
+	smallSize := w32.GetSystemMetrics(w32.SM_CXSMICON)
+	bigSize := w32.GetSystemMetrics(w32.SM_CXICON)
+	small := closestSizedImage(images, smallSize)
+	big := closestSizedImage(images, bigSize)
+
+	smallIcon, err := createIconFromImage(small)
+	if err != nil {
+		return err
+	}
+	bigIcon, err := createIconFromImage(big)
+	if err != nil {
+		w32.DestroyIcon(smallIcon)
+		return err
+	}
+	setWindowIcon(window, smallIcon, smallIcon, bigIcon)
+	return nil
+}
+
+// closestSizedImage returns the image from images whose width is closest to
+// size.
+func closestSizedImage(images []image.Image, size int) image.Image {
+	best := images[0]
+	bestDiff := abs(best.Bounds().Dx() - size)
+	for _, img := range images[1:] {
+		diff := abs(img.Bounds().Dx() - size)
+		if diff < bestDiff {
+			best = img
+			bestDiff = diff
+		}
+	}
+	return best
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// setWindowIcon sends the WM_SETICON messages for the given icon handles and
+// destroys the window's previously set icons, if any, replacing them with
+// small and big (the new handles that own the window's icons from now on).
+func setWindowIcon(window w32.HWND, small, small2, big w32.HICON) {
+	w32.SendMessage(window, w32.WM_SETICON, w32.ICON_SMALL, uintptr(small))
+	w32.SendMessage(window, w32.WM_SETICON, w32.ICON_SMALL2, uintptr(small2))
+	w32.SendMessage(window, w32.WM_SETICON, w32.ICON_BIG, uintptr(big))
+
+	windowIconsMu.Lock()
+	old, ok := windowIcons[window]
+	windowIcons[window] = windowIconPair{Small: small, Big: big}
+	windowIconsMu.Unlock()
+	if ok {
+		destroyIconPair(old, windowIconPair{Small: small, Big: big})
+	}
+}
+
+// destroyIconPair destroys every handle in old that is not reused in kept,
+// without destroying the same handle twice when old.Small == old.Big.
+func destroyIconPair(old, kept windowIconPair) {
+	destroyed := make(map[w32.HICON]bool, 2)
+	for _, icon := range [...]w32.HICON{old.Small, old.Big} {
+		if icon == 0 || icon == kept.Small || icon == kept.Big || destroyed[icon] {
+			continue
+		}
+		w32.DestroyIcon(icon)
+		destroyed[icon] = true
+	}
+}
+
+// ReleaseWindowIcon destroys the icon(s) that were set on window by
+// SetIconFromImage or SetIconsFromImages and forgets about them. Call this
+// from your WM_DESTROY handling to avoid leaking the icon handles; it is a
+// no-op if no such icon was ever set.
+func ReleaseWindowIcon(window w32.HWND) {
+	windowIconsMu.Lock()
+	old, ok := windowIcons[window]
+	delete(windowIcons, window)
+	windowIconsMu.Unlock()
+	if ok {
+		destroyIconPair(old, windowIconPair{})
+	}
+}
+
+// createIconFromImage converts img to a 32-bit BGRA buffer and creates an
+// icon from it using CreateIcon. The alpha channel of img is preserved so the
+// icon's AND mask is simply left blank (Windows XP and later ignore the AND
+// mask for 32-bit color icons that carry their own alpha channel).
+func createIconFromImage(img image.Image) (w32.HICON, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return 0, errors.New("win.createIconFromImage: image has no size")
+	}
+
+	xorBits := make([]byte, width*height*4)
+	i := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			xorBits[i+0] = byte(b >> 8)
+			xorBits[i+1] = byte(g >> 8)
+			xorBits[i+2] = byte(r >> 8)
+			xorBits[i+3] = byte(a >> 8)
+			i += 4
+		}
+	}
+
+	andBits := make([]byte, ((width+31)/32)*4*height)
+
+	icon := w32.CreateIcon(
+		w32.GetModuleHandle(""),
+		width, height,
+		1, 32,
+		&andBits[0], &xorBits[0],
+	)
+	if icon == 0 {
+		return 0, errors.New("win.createIconFromImage: CreateIcon failed")
+	}
+	return icon, nil
+}
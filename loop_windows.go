@@ -0,0 +1,150 @@
+package win
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gonutz/w32"
+)
+
+// Accelerator is one entry of an accelerator table passed to SetAccelerators,
+// mirroring w32.ACCEL.
+type Accelerator struct {
+	Flags byte
+	Key   uint16
+	Cmd   uint16
+}
+
+var (
+	loopMu            sync.Mutex
+	topLevelWindows   = make(map[w32.HWND]bool)
+	modelessDialogs   = make(map[w32.HWND]bool)
+	acceleratorTables = make(map[w32.HWND]w32.HACCEL)
+)
+
+// trackTopLevelWindow registers window as one of the application's top-level
+// windows. RunMainLoop and RunMainGameLoop keep running until all of them
+// have been destroyed, not just until the first one posts WM_QUIT.
+func trackTopLevelWindow(window w32.HWND) {
+	loopMu.Lock()
+	topLevelWindows[window] = true
+	loopMu.Unlock()
+}
+
+// RegisterModelessDialog tells RunMainLoop and RunMainGameLoop about a
+// modeless dialog box so their message pump can route keyboard navigation
+// (Tab, arrow keys, Enter, Escape, ...) to it via IsDialogMessage. Without
+// this, modeless dialogs silently swallow such input.
+func RegisterModelessDialog(dialog w32.HWND) {
+	loopMu.Lock()
+	modelessDialogs[dialog] = true
+	loopMu.Unlock()
+}
+
+// UnregisterModelessDialog undoes RegisterModelessDialog. Call it once the
+// dialog is destroyed.
+func UnregisterModelessDialog(dialog w32.HWND) {
+	loopMu.Lock()
+	delete(modelessDialogs, dialog)
+	loopMu.Unlock()
+}
+
+// SetAccelerators installs an accelerator table for window, created via
+// CreateAcceleratorTable, so that RunMainLoop and RunMainGameLoop translate
+// matching key combinations into WM_COMMAND/WM_SYSCOMMAND messages for
+// window before the usual TranslateMessage/DispatchMessage handling. Pass an
+// empty slice to remove window's accelerator table.
+func SetAccelerators(window w32.HWND, accelerators []Accelerator) error {
+	loopMu.Lock()
+	oldTable, hadOld := acceleratorTables[window]
+	loopMu.Unlock()
+
+	if len(accelerators) == 0 {
+		if hadOld {
+			w32.DestroyAcceleratorTable(oldTable)
+			loopMu.Lock()
+			delete(acceleratorTables, window)
+			loopMu.Unlock()
+		}
+		return nil
+	}
+
+	raw := make([]w32.ACCEL, len(accelerators))
+	for i, a := range accelerators {
+		raw[i] = w32.ACCEL{FVirt: a.Flags, Key: a.Key, Cmd: a.Cmd}
+	}
+	table := w32.CreateAcceleratorTable(raw)
+	if table == 0 {
+		return errors.New("win.SetAccelerators: CreateAcceleratorTable failed")
+	}
+
+	loopMu.Lock()
+	acceleratorTables[window] = table
+	loopMu.Unlock()
+	if hadOld {
+		w32.DestroyAcceleratorTable(oldTable)
+	}
+	return nil
+}
+
+// pumpMessage dispatches one already retrieved message, first trying the
+// accelerator table registered for the message's own top-level window and
+// then every registered modeless dialog, falling through to the normal
+// TranslateMessage/DispatchMessage handling if neither consumes it.
+func pumpMessage(msg *w32.MSG) {
+	owner := w32.GetAncestor(msg.Hwnd, w32.GA_ROOT)
+
+	loopMu.Lock()
+	table, hasTable := acceleratorTables[owner]
+	dialogs := make([]w32.HWND, 0, len(modelessDialogs))
+	for d := range modelessDialogs {
+		dialogs = append(dialogs, d)
+	}
+	loopMu.Unlock()
+
+	if hasTable && w32.TranslateAccelerator(owner, table, msg) != 0 {
+		return
+	}
+	for _, dialog := range dialogs {
+		if w32.IsDialogMessage(dialog, msg) {
+			return
+		}
+	}
+
+	w32.TranslateMessage(msg)
+	w32.DispatchMessage(msg)
+}
+
+// anyOwnedWindowAlive reports whether any top-level window created via
+// NewWindow (directly or through NewWindowDPI/NewWindowWithHandler), or any
+// registered modeless dialog, is still alive. RunMainLoop and
+// RunMainGameLoop use this so that multi-window applications keep running
+// after one window closes and posts WM_QUIT, exiting only once every
+// top-level window is gone. Dead windows are pruned from the registries as
+// they are found.
+func anyOwnedWindowAlive() bool {
+	loopMu.Lock()
+	defer loopMu.Unlock()
+	alive := false
+	for w := range topLevelWindows {
+		if w32.IsWindow(w) {
+			alive = true
+		} else {
+			delete(topLevelWindows, w)
+		}
+	}
+	for w := range modelessDialogs {
+		if w32.IsWindow(w) {
+			alive = true
+		} else {
+			delete(modelessDialogs, w)
+		}
+	}
+	for w, table := range acceleratorTables {
+		if !w32.IsWindow(w) {
+			w32.DestroyAcceleratorTable(table)
+			delete(acceleratorTables, w)
+		}
+	}
+	return alive
+}
@@ -0,0 +1,180 @@
+package win
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/gonutz/w32"
+)
+
+// Standard HID usage page and usage IDs for the device kinds accepted by
+// RegisterRawInput.
+const (
+	hidUsagePageGeneric = 0x01
+	hidUsageMouse       = 0x02
+	hidUsageKeyboard    = 0x06
+	hidUsageGamepad     = 0x05
+)
+
+// RawInputDeviceKind selects which kind of device a RawInputDevice
+// registration is for.
+type RawInputDeviceKind int
+
+const (
+	RawInputMouse RawInputDeviceKind = iota
+	RawInputKeyboard
+	RawInputGamepad
+)
+
+// RawInputDevice describes one device to be registered for raw input via
+// RegisterRawInput. Flags is typically 0, or w32.RIDEV_INPUTSINK to keep
+// receiving input while window does not have focus.
+type RawInputDevice struct {
+	Kind  RawInputDeviceKind
+	Flags uint32
+}
+
+// RegisterRawInput registers window to receive WM_INPUT messages for the
+// given devices, wrapping RegisterRawInputDevices. Decode the resulting
+// messages with DecodeRawInput.
+func RegisterRawInput(window w32.HWND, devices ...RawInputDevice) error {
+	if len(devices) == 0 {
+		return errors.New("win.RegisterRawInput: no devices given")
+	}
+
+	raw := make([]w32.RAWINPUTDEVICE, len(devices))
+	for i, d := range devices {
+		raw[i].UsagePage = hidUsagePageGeneric
+		raw[i].Flags = d.Flags
+		raw[i].Target = window
+		switch d.Kind {
+		case RawInputMouse:
+			raw[i].Usage = hidUsageMouse
+		case RawInputKeyboard:
+			raw[i].Usage = hidUsageKeyboard
+		case RawInputGamepad:
+			raw[i].Usage = hidUsageGamepad
+		default:
+			return errors.New("win.RegisterRawInput: unknown device kind")
+		}
+	}
+
+	if !w32.RegisterRawInputDevices(raw) {
+		return errors.New("win.RegisterRawInput: RegisterRawInputDevices failed")
+	}
+	return nil
+}
+
+// RawMouse is the decoded payload of a WM_INPUT message for a mouse device,
+// as returned by DecodeRawInput.
+type RawMouse struct {
+	// DX and DY are the relative movement since the last raw mouse message,
+	// in device units, unaffected by display scaling or pointer acceleration.
+	DX, DY int
+	// ButtonDown and ButtonUp report which of MouseButtonLeft,
+	// MouseButtonRight and MouseButtonMiddle changed state in this message.
+	ButtonDown, ButtonUp MouseButtons
+}
+
+// RawKeyboard is the decoded payload of a WM_INPUT message for a keyboard
+// device, as returned by DecodeRawInput.
+type RawKeyboard struct {
+	MakeCode uint16
+	Key      VirtualKey
+	IsUp     bool
+	IsE0     bool
+}
+
+// RawHID is the decoded payload of a WM_INPUT message for a HID device such
+// as a gamepad, as returned by DecodeRawInput. Data is the raw HID report as
+// delivered by the device driver; interpreting it (e.g. mapping bytes to
+// buttons and axes) depends on the specific device.
+type RawHID struct {
+	Data []byte
+}
+
+// RawInput is the decoded payload of a WM_INPUT message, as returned by
+// DecodeRawInput. Exactly one of Mouse, Keyboard or HID is non-nil, matching
+// the device that generated the event.
+type RawInput struct {
+	Mouse    *RawMouse
+	Keyboard *RawKeyboard
+	HID      *RawHID
+}
+
+// DecodeRawInput retrieves and decodes the raw input data referenced by a
+// WM_INPUT message's lParam, via GetRawInputData. It first asks Windows for
+// the required buffer size so HID reports (e.g. from a gamepad), which are
+// larger than a fixed RAWINPUT and vary by device, are read in full.
+func DecodeRawInput(lParam uintptr) (RawInput, error) {
+	headerSize := uint32(unsafe.Sizeof(w32.RAWINPUTHEADER{}))
+
+	var size uint32
+	if w32.GetRawInputData(w32.HRAWINPUT(lParam), w32.RID_INPUT, nil, &size, headerSize) != 0 {
+		return RawInput{}, errors.New("win.DecodeRawInput: GetRawInputData (size query) failed")
+	}
+	buf := make([]byte, size)
+
+	n := w32.GetRawInputData(
+		w32.HRAWINPUT(lParam),
+		w32.RID_INPUT,
+		unsafe.Pointer(&buf[0]),
+		&size,
+		headerSize,
+	)
+	if n == 0xFFFFFFFF || n == 0 {
+		return RawInput{}, errors.New("win.DecodeRawInput: GetRawInputData failed")
+	}
+	raw := (*w32.RAWINPUT)(unsafe.Pointer(&buf[0]))
+
+	switch raw.Header.Type {
+	case w32.RIM_TYPEMOUSE:
+		m := raw.Mouse
+		var down, up MouseButtons
+		flags := m.ButtonFlags
+		if flags&w32.RI_MOUSE_LEFT_BUTTON_DOWN != 0 {
+			down |= MouseButtonLeft
+		}
+		if flags&w32.RI_MOUSE_LEFT_BUTTON_UP != 0 {
+			up |= MouseButtonLeft
+		}
+		if flags&w32.RI_MOUSE_RIGHT_BUTTON_DOWN != 0 {
+			down |= MouseButtonRight
+		}
+		if flags&w32.RI_MOUSE_RIGHT_BUTTON_UP != 0 {
+			up |= MouseButtonRight
+		}
+		if flags&w32.RI_MOUSE_MIDDLE_BUTTON_DOWN != 0 {
+			down |= MouseButtonMiddle
+		}
+		if flags&w32.RI_MOUSE_MIDDLE_BUTTON_UP != 0 {
+			up |= MouseButtonMiddle
+		}
+		return RawInput{Mouse: &RawMouse{
+			DX:         int(m.LastX),
+			DY:         int(m.LastY),
+			ButtonDown: down,
+			ButtonUp:   up,
+		}}, nil
+	case w32.RIM_TYPEKEYBOARD:
+		k := raw.Keyboard
+		return RawInput{Keyboard: &RawKeyboard{
+			MakeCode: k.MakeCode,
+			Key:      VirtualKey(k.VKey),
+			IsUp:     k.Flags&w32.RI_KEY_BREAK != 0,
+			IsE0:     k.Flags&w32.RI_KEY_E0 != 0,
+		}}, nil
+	case w32.RIM_TYPEHID:
+		hid := raw.Hid
+		reportSize := int(hid.SizeHid) * int(hid.Count)
+		dataOffset := int(unsafe.Offsetof(raw.Hid)) + int(unsafe.Sizeof(hid.SizeHid)) + int(unsafe.Sizeof(hid.Count))
+		if reportSize < 0 || dataOffset+reportSize > len(buf) {
+			return RawInput{}, errors.New("win.DecodeRawInput: malformed HID report")
+		}
+		data := make([]byte, reportSize)
+		copy(data, buf[dataOffset:dataOffset+reportSize])
+		return RawInput{HID: &RawHID{Data: data}}, nil
+	default:
+		return RawInput{}, errors.New("win.DecodeRawInput: unsupported device type")
+	}
+}
@@ -0,0 +1,211 @@
+package win
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+
+	"github.com/gonutz/w32"
+)
+
+// trayCallbackBase is the base of the private window messages used to
+// deliver tray icon notifications (mouse clicks, balloon clicks, etc.) from
+// Windows back to the owning window. Each TrayIcon gets its own message ID,
+// trayCallbackBase plus the icon's ID, so several tray icons can be owned by
+// the same window without colliding.
+const trayCallbackBase = w32.WM_APP + 0x500
+
+var (
+	trayMu     sync.Mutex
+	nextTrayID uint32 = 1
+)
+
+// BalloonKind selects the icon shown next to a tray balloon notification.
+type BalloonKind uint32
+
+const (
+	BalloonNone    BalloonKind = BalloonKind(w32.NIIF_NONE)
+	BalloonInfo    BalloonKind = BalloonKind(w32.NIIF_INFO)
+	BalloonWarning BalloonKind = BalloonKind(w32.NIIF_WARNING)
+	BalloonError   BalloonKind = BalloonKind(w32.NIIF_ERROR)
+)
+
+// MenuItem describes one entry in a TrayIcon's right-click context menu. Set
+// Separator to true for a separator line, in which case ID and Text are
+// ignored. ID is the value that will be reported to the menu handler passed
+// to SetMenu when this item is chosen.
+type MenuItem struct {
+	ID        uint32
+	Text      string
+	Separator bool
+}
+
+// TrayIcon is a notification area ("system tray") icon, created with
+// NewTrayIcon. It wraps Shell_NotifyIcon and, optionally, a right-click
+// context menu built from CreatePopupMenu/AppendMenu.
+type TrayIcon struct {
+	window  w32.HWND
+	id      uint32
+	message uint32
+	menu    w32.HMENU
+	onMenu  func(id uint32)
+}
+
+// NewTrayIcon adds a new icon to the notification area of the task bar,
+// owned by window. Mouse and balloon notifications for the icon are
+// delivered to window's MessageCallback as the private message returned by
+// the icon's notification message ID; callers that want to react to clicks
+// should use SetMenu together with a handler, and call HandleTrayMessage
+// from their MessageCallback.
+func NewTrayIcon(window w32.HWND, icon w32.HICON, tooltip string) (*TrayIcon, error) {
+	trayMu.Lock()
+	id := nextTrayID
+	nextTrayID++
+	trayMu.Unlock()
+
+	t := &TrayIcon{
+		window:  window,
+		id:      id,
+		message: trayCallbackBase + id,
+	}
+
+	data := w32.NOTIFYICONDATA{
+		HWnd:            window,
+		UID:             id,
+		Flags:           w32.NIF_ICON | w32.NIF_MESSAGE | w32.NIF_TIP,
+		CallbackMessage: t.message,
+		Icon:            icon,
+	}
+	copy(data.Tip[:], syscall.StringToUTF16(tooltip))
+
+	if !w32.Shell_NotifyIcon(w32.NIM_ADD, &data) {
+		return nil, errors.New("win.NewTrayIcon: Shell_NotifyIcon(NIM_ADD) failed")
+	}
+	return t, nil
+}
+
+// Message returns the private WM_APP based message that Windows sends to the
+// owning window whenever the mouse interacts with this tray icon. Decode it
+// with HandleTrayMessage from inside your MessageCallback.
+func (t *TrayIcon) Message() uint32 {
+	return t.message
+}
+
+// SetIcon replaces the tray icon's image.
+func (t *TrayIcon) SetIcon(icon w32.HICON) error {
+	data := w32.NOTIFYICONDATA{
+		HWnd:  t.window,
+		UID:   t.id,
+		Flags: w32.NIF_ICON,
+		Icon:  icon,
+	}
+	if !w32.Shell_NotifyIcon(w32.NIM_MODIFY, &data) {
+		return errors.New("win.TrayIcon.SetIcon: Shell_NotifyIcon(NIM_MODIFY) failed")
+	}
+	return nil
+}
+
+// SetTooltip replaces the text shown when the mouse hovers over the tray
+// icon.
+func (t *TrayIcon) SetTooltip(tooltip string) error {
+	data := w32.NOTIFYICONDATA{
+		HWnd:  t.window,
+		UID:   t.id,
+		Flags: w32.NIF_TIP,
+	}
+	copy(data.Tip[:], syscall.StringToUTF16(tooltip))
+	if !w32.Shell_NotifyIcon(w32.NIM_MODIFY, &data) {
+		return errors.New("win.TrayIcon.SetTooltip: Shell_NotifyIcon(NIM_MODIFY) failed")
+	}
+	return nil
+}
+
+// ShowBalloon pops up a balloon notification above the tray icon with the
+// given title, text and icon kind.
+func (t *TrayIcon) ShowBalloon(title, text string, kind BalloonKind) error {
+	data := w32.NOTIFYICONDATA{
+		HWnd:      t.window,
+		UID:       t.id,
+		Flags:     w32.NIF_INFO,
+		InfoFlags: uint32(kind),
+	}
+	copy(data.InfoTitle[:], syscall.StringToUTF16(title))
+	copy(data.Info[:], syscall.StringToUTF16(text))
+	if !w32.Shell_NotifyIcon(w32.NIM_MODIFY, &data) {
+		return errors.New("win.TrayIcon.ShowBalloon: Shell_NotifyIcon(NIM_MODIFY) failed")
+	}
+	return nil
+}
+
+// SetMenu defines the right-click context menu for this tray icon and the
+// handler that is called with the ID of the chosen MenuItem. The menu is
+// rebuilt from items every time SetMenu is called. Pass a nil handler to
+// remove the menu.
+func (t *TrayIcon) SetMenu(items []MenuItem, handler func(id uint32)) error {
+	if t.menu != 0 {
+		w32.DestroyMenu(t.menu)
+		t.menu = 0
+	}
+	t.onMenu = handler
+	if len(items) == 0 || handler == nil {
+		return nil
+	}
+
+	menu := w32.CreatePopupMenu()
+	if menu == 0 {
+		return errors.New("win.TrayIcon.SetMenu: CreatePopupMenu failed")
+	}
+	for _, item := range items {
+		if item.Separator {
+			w32.AppendMenu(menu, w32.MF_SEPARATOR, 0, "")
+		} else {
+			w32.AppendMenu(menu, w32.MF_STRING, uintptr(item.ID), item.Text)
+		}
+	}
+	t.menu = menu
+	return nil
+}
+
+// Remove deletes the icon from the notification area and destroys its
+// context menu, if any. The TrayIcon must not be used after calling Remove.
+func (t *TrayIcon) Remove() error {
+	data := w32.NOTIFYICONDATA{HWnd: t.window, UID: t.id}
+	ok := w32.Shell_NotifyIcon(w32.NIM_DELETE, &data)
+	if t.menu != 0 {
+		w32.DestroyMenu(t.menu)
+		t.menu = 0
+	}
+	if !ok {
+		return errors.New("win.TrayIcon.Remove: Shell_NotifyIcon(NIM_DELETE) failed")
+	}
+	return nil
+}
+
+// HandleTrayMessage handles msg/w/l if they are this tray icon's private
+// notification message, showing the context menu on a right-click and
+// calling the handler passed to SetMenu when an item is chosen. It returns
+// true if the message was handled by this tray icon.
+func (t *TrayIcon) HandleTrayMessage(msg uint32, w, l uintptr) bool {
+	if msg == t.message {
+		event := uint32(l)
+		if event == w32.WM_RBUTTONUP && t.menu != 0 {
+			var cursor w32.POINT
+			w32.GetCursorPos(&cursor)
+			w32.SetForegroundWindow(t.window)
+			cmd := w32.TrackPopupMenu(
+				t.menu,
+				w32.TPM_RETURNCMD|w32.TPM_RIGHTBUTTON,
+				int(cursor.X), int(cursor.Y), 0, t.window, nil,
+			)
+			// Required so the popup menu closes properly if the user clicks
+			// away instead of choosing an item; see the Notification Icon
+			// Guidelines on MSDN.
+			w32.PostMessage(t.window, w32.WM_NULL, 0, 0)
+			if cmd != 0 && t.onMenu != nil {
+				t.onMenu(uint32(cmd))
+			}
+		}
+		return true
+	}
+	return false
+}
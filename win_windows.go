@@ -34,6 +34,7 @@ func NewWindow(x, y, width, height int, className string, f MessageCallback) (w3
 	if window == 0 {
 		return 0, errors.New("win.NewWindow: CreateWindowEx failed")
 	}
+	trackTopLevelWindow(window)
 	return window, nil
 }
 
@@ -119,19 +120,30 @@ func DisableFullscreen(window w32.HWND, placement w32.WINDOWPLACEMENT) {
 }
 
 // RunMainLoop starts the applications window message handling. It loops until
-// the window is closed. Messages are forwarded to the handler function that was
-// passed to NewWindow.
+// every top-level window created with NewWindow has been destroyed, not just
+// until the first one closes. Messages are forwarded to the handler function
+// that was passed to NewWindow, after first being offered to any accelerator
+// table registered with SetAccelerators and any modeless dialog registered
+// with RegisterModelessDialog.
 func RunMainLoop() {
 	var msg w32.MSG
-	for w32.GetMessage(&msg, 0, 0, 0) != 0 {
-		w32.TranslateMessage(&msg)
-		w32.DispatchMessage(&msg)
+	for {
+		if w32.GetMessage(&msg, 0, 0, 0) == 0 {
+			if anyOwnedWindowAlive() {
+				continue
+			}
+			return
+		}
+		pumpMessage(&msg)
 	}
 }
 
 // RunMainGameLoop starts the applications window message handling. It loops
-// until the window is closed. Messages are forwarded to the handler function
-// that was passed to NewWindow.
+// until every top-level window created with NewWindow has been destroyed,
+// not just until the first one closes. Messages are forwarded to the handler
+// function that was passed to NewWindow, after first being offered to any
+// accelerator table registered with SetAccelerators and any modeless dialog
+// registered with RegisterModelessDialog.
 // In contrast to RunMainLoop, RunMainGameLoop calls the given function whenever
 // there are now messages to be handled at the moment. You can use this like a
 // classical DOS era endless loop to run any real-time logic in between
@@ -142,10 +154,9 @@ func RunMainLoop() {
 func RunMainGameLoop(f func()) {
 	var msg w32.MSG
 	w32.PeekMessage(&msg, 0, 0, 0, w32.PM_NOREMOVE)
-	for msg.Message != w32.WM_QUIT {
+	for msg.Message != w32.WM_QUIT || anyOwnedWindowAlive() {
 		if w32.PeekMessage(&msg, 0, 0, 0, w32.PM_REMOVE) {
-			w32.TranslateMessage(&msg)
-			w32.DispatchMessage(&msg)
+			pumpMessage(&msg)
 		} else {
 			f()
 		}